@@ -7,17 +7,19 @@
 package cobramcp
 
 import (
-	"fmt"
+	"crypto/tls"
 	"log/slog"
 	"net/http"
 	"os"
 	"time"
 
+	"github.com/eat-pray-ai/cobra-mcp/internal/metrics"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 )
 
 const (
+	mcpUse    = "mcp"
 	mcpShort  = "Start MCP server"
 	mcpLong   = "Start MCP server to handle requests from clients"
 	modeUsage = "stdio|http"
@@ -51,13 +53,104 @@ type Config struct {
 	// ServerOptions allows overriding the full MCP server options.
 	// When set, Instructions, PageSize, and KeepAlive are ignored.
 	ServerOptions *mcp.ServerOptions
+
+	// ToolMiddlewares wrap every handler created by GenToolHandler, applied
+	// in order around the op call. Use WithToolMiddleware to append.
+	ToolMiddlewares []ToolMiddleware
+
+	// ResourceMiddlewares wrap every handler created by GenResourceHandler,
+	// applied in order around the op call. Use WithResourceMiddleware to
+	// append.
+	ResourceMiddlewares []ResourceMiddleware
+
+	// DisableRequestLogging turns off the default input/output logging
+	// middleware that GenToolHandler and GenResourceHandler install.
+	DisableRequestLogging bool
+
+	// Observability configures the expvar, Prometheus, pprof, and health
+	// endpoints mounted in HTTP mode. Nil (the default) disables them.
+	Observability *ObservabilityOptions
+
+	// ListenProto selects the listener network for HTTP mode: "tcp"
+	// (the default) or "unix".
+	ListenProto string
+
+	// ListenAddr overrides the address HTTP mode listens on. For "tcp" it
+	// defaults to ":<port>" from the --port flag; for "unix" it must be
+	// set to a socket path.
+	ListenAddr string
+
+	// CertFile and KeyFile enable TLS for HTTP mode via PEM files.
+	// Ignored when TLSConfig is set.
+	CertFile string
+	KeyFile  string
+
+	// TLSConfig, if set, takes precedence over CertFile/KeyFile.
+	TLSConfig *tls.Config
+
+	// ReadHeaderTimeout bounds how long HTTP mode waits to read request
+	// headers. Defaults to 10s if zero.
+	ReadHeaderTimeout time.Duration
+
+	// ShutdownGracePeriod bounds how long HTTP mode waits for in-flight
+	// requests to finish when the command's context is canceled.
+	// Defaults to 10s if zero.
+	ShutdownGracePeriod time.Duration
+
+	// Authenticator, if set, is invoked for every HTTP mode request before
+	// it reaches the MCP handler. A non-nil error fails the request with
+	// 401 Unauthorized, or 403 Forbidden if the error wraps ErrForbidden.
+	Authenticator func(*http.Request) error
+
+	// Manifest is the path to a YAML or JSON file declaring tools to
+	// register, as an alternative to wiring each one up in Go. See
+	// RegisterManifest.
+	Manifest string
+
+	// LoggerFactory builds the per-call logger for a tool or resource
+	// handler. When nil, a default logger backed by mcp.NewLoggingHandler
+	// (deduped to one message per second per name) is used. Supply one to
+	// attach request IDs, change the dedup sampler, or route to a custom
+	// slog.Handler.
+	LoggerFactory func(session *mcp.ServerSession, name string) *slog.Logger
+
+	// LogOutputs includes the tool's input (redacted and capped per
+	// RedactInput/InputLogCap) and output_length in the default logging
+	// middleware's log line. Off by default to avoid leaking tool
+	// arguments into logs.
+	LogOutputs bool
+
+	// InputLogCap bounds how many bytes of a tool's JSON input are logged
+	// when LogOutputs is set. Defaults to 2048 if zero.
+	InputLogCap int
+
+	// RedactInput, if set, is applied to a tool's marshaled JSON input
+	// before it is logged, letting callers scrub sensitive fields.
+	RedactInput func(inputJSON string) string
+
+	// metrics backs Observability once ServerAndCommand wires it up.
+	metrics *metrics.Metrics
+
+	// registeredTools backs `mcp dump-manifest`.
+	registeredTools []ManifestTool
 }
 
 // ServerAndCommand creates a new MCP server and a cobra command that starts
 // it. The caller registers tools and resources on the returned server, then
 // adds the returned command to their root cobra command.
 func ServerAndCommand(cfg *Config) (*mcp.Server, *cobra.Command) {
+	if cfg.Observability != nil && cfg.Observability.Enabled {
+		cfg.metrics = metrics.New(cfg.Name)
+		cfg.WithToolMiddleware(MetricsMiddleware(cfg.metrics))
+	}
+
 	server := newServer(cfg)
+
+	if err := RegisterManifest(cfg, server); err != nil {
+		slog.Error("loading manifest", "manifest", cfg.Manifest, "error", err)
+		os.Exit(1)
+	}
+
 	cmd := newCommand(cfg, server)
 	return server, cmd
 }
@@ -111,13 +204,12 @@ func newCommand(cfg *Config, server *mcp.Server) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   "mcp",
+		Use:   mcpUse,
 		Short: mcpShort,
 		Long:  mcpLong,
 		Run: func(cmd *cobra.Command, args []string) {
 			var err error
 			ctx := cmd.Context()
-			addr := fmt.Sprintf(":%d", port)
 			slog.InfoContext(
 				ctx, "starting MCP server",
 				"mode", mode,
@@ -132,16 +224,7 @@ func newCommand(cfg *Config, server *mcp.Server) *cobra.Command {
 				}
 				err = server.Run(ctx, t)
 			case "http":
-				handler := mcp.NewStreamableHTTPHandler(
-					func(*http.Request) *mcp.Server {
-						return server
-					}, nil,
-				)
-				slog.InfoContext(
-					ctx, "http server configuration",
-					"url", fmt.Sprintf("http://localhost:%d/mcp", port),
-				)
-				err = http.ListenAndServe(addr, handler)
+				err = runHTTP(ctx, cfg, server, port)
 			default:
 				slog.ErrorContext(
 					ctx, "invalid mode",
@@ -163,5 +246,10 @@ func newCommand(cfg *Config, server *mcp.Server) *cobra.Command {
 	cmd.Flags().StringVarP(&mode, "mode", "m", "stdio", modeUsage)
 	cmd.Flags().IntVarP(&port, "port", "p", defaultPort, portUsage)
 
+	cmd.AddCommand(newInstallCommand(cfg))
+	cmd.AddCommand(newUninstallCommand(cfg))
+	cmd.AddCommand(newValidateManifestCommand(cfg))
+	cmd.AddCommand(newDumpManifestCommand(cfg))
+
 	return cmd
 }
@@ -0,0 +1,27 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import "fmt"
+
+// ToolError is a typed tool failure carrying a stable machine-readable
+// code, a message safe to show to MCP clients, and optional structured
+// fields for diagnostics. GenToolHandler detects it and returns a
+// CallToolResult{IsError: true} instead of surfacing the raw Go error, so
+// clients get a useful result rather than an opaque RPC failure.
+type ToolError struct {
+	Code    string
+	Message string
+	Fields  map[string]any
+}
+
+// NewToolError creates a ToolError with the given code, user-safe message,
+// and optional structured fields for logging.
+func NewToolError(code, message string, fields map[string]any) *ToolError {
+	return &ToolError{Code: code, Message: message, Fields: fields}
+}
+
+func (e *ToolError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestGenToolHandlerReturnsToolErrorAsResult(t *testing.T) {
+	cfg := &Config{DisableRequestLogging: true}
+	op := func(_ struct{}, _ io.Writer) error {
+		return NewToolError("not_found", "widget does not exist", map[string]any{"id": "42"})
+	}
+	handler := GenToolHandler(cfg, "get_widget", op)
+
+	result, _, err := handler(
+		t.Context(), &mcp.CallToolRequest{}, struct{}{},
+	)
+	if err != nil {
+		t.Fatalf("a ToolError should surface as a result, not a Go error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected IsError to be true for a ToolError")
+	}
+
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || text.Text != "widget does not exist" {
+		t.Fatalf("expected the user-safe message as content, got %+v", result.Content[0])
+	}
+	if result.Meta["code"] != "not_found" {
+		t.Fatalf("expected Meta[code] to carry the ToolError's code, got %+v", result.Meta)
+	}
+	fields, _ := result.Meta["fields"].(map[string]any)
+	if fields["id"] != "42" {
+		t.Fatalf("expected Meta[fields] to carry the ToolError's fields, got %+v", result.Meta)
+	}
+}
+
+func TestGenToolHandlerSurfacesPlainErrors(t *testing.T) {
+	cfg := &Config{DisableRequestLogging: true}
+	wantErr := fmt.Errorf("boom")
+	op := func(_ struct{}, _ io.Writer) error {
+		return wantErr
+	}
+	handler := GenToolHandler(cfg, "get_widget", op)
+
+	result, _, err := handler(t.Context(), &mcp.CallToolRequest{}, struct{}{})
+	if err != wantErr {
+		t.Fatalf("a plain error should surface unchanged, got %v", err)
+	}
+	if result != nil {
+		t.Fatalf("expected a nil result alongside a plain Go error, got %+v", result)
+	}
+}
+
+func TestGenToolHandlerReturnsOutputOnSuccess(t *testing.T) {
+	cfg := &Config{DisableRequestLogging: true}
+	op := func(_ struct{}, w io.Writer) error {
+		_, err := w.Write([]byte("ok"))
+		return err
+	}
+	handler := GenToolHandler(cfg, "get_widget", op)
+
+	result, _, err := handler(t.Context(), &mcp.CallToolRequest{}, struct{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatal("expected a successful result")
+	}
+	text, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || text.Text != "ok" {
+		t.Fatalf("expected the written output as content, got %+v", result.Content[0])
+	}
+}
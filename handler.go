@@ -7,92 +7,120 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
-	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // GenToolHandler creates a typed MCP tool handler that deserializes JSON input
-// into T, calls op, and returns the written output as text content.
+// into T, calls op, and returns the written output as text content. The
+// handler is wrapped with cfg.ToolMiddlewares, plus a default logging
+// middleware unless cfg.DisableRequestLogging is set.
 func GenToolHandler[T any](
-	toolName string, op func(T, io.Writer) error,
+	cfg *Config, toolName string, op func(T, io.Writer) error,
 ) mcp.ToolHandlerFor[T, any] {
 	return func(
 		ctx context.Context, req *mcp.CallToolRequest, input T,
 	) (*mcp.CallToolResult, any, error) {
-		logger := slog.New(
-			mcp.NewLoggingHandler(
-				req.Session,
-				&mcp.LoggingHandlerOptions{
-					LoggerName: toolName, MinInterval: time.Second,
-				},
-			),
-		)
-
-		var writer bytes.Buffer
-		err := op(input, &writer)
-
 		inputJSON, _ := json.Marshal(input)
+		ctx = withToolCall(ctx, &toolCall{name: toolName, inputJSON: string(inputJSON)})
 
-		if err != nil {
-			logger.ErrorContext(ctx, err.Error(), "input", string(inputJSON))
-			slog.ErrorContext(
-				ctx, err.Error(), "tool", toolName, "input", string(inputJSON),
-			)
-			return nil, nil, err
+		core := func(
+			ctx context.Context, req *mcp.CallToolRequest,
+		) (*mcp.CallToolResult, any, error) {
+			var writer bytes.Buffer
+			if err := op(input, &writer); err != nil {
+				var toolErr *ToolError
+				if errors.As(err, &toolErr) {
+					return &mcp.CallToolResult{
+						IsError: true,
+						Content: []mcp.Content{&mcp.TextContent{Text: toolErr.Message}},
+						Meta: mcp.Meta{
+							"code":   toolErr.Code,
+							"fields": toolErr.Fields,
+						},
+					}, nil, nil
+				}
+				return nil, nil, err
+			}
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: writer.String()}},
+			}, nil, nil
 		}
 
-		logger.InfoContext(
-			ctx, toolName,
-			"input", string(inputJSON), "output_length", writer.Len(),
-		)
-		slog.InfoContext(
-			ctx, toolName,
-			"input", string(inputJSON), "output_length", writer.Len(),
-		)
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{&mcp.TextContent{Text: writer.String()}},
-		}, nil, nil
+		return chainTool(core, toolMiddlewares(cfg))(ctx, req)
+	}
+}
+
+// toolMiddlewares builds the full middleware chain for a tool handler,
+// prepending the default logging middleware unless disabled.
+func toolMiddlewares(cfg *Config) []ToolMiddleware {
+	mws := make([]ToolMiddleware, 0, len(cfg.ToolMiddlewares)+1)
+	if !cfg.DisableRequestLogging {
+		mws = append(mws, LoggingMiddleware(cfg))
 	}
+	return append(mws, cfg.ToolMiddlewares...)
 }
 
 // GenResourceHandler creates an MCP resource handler that calls op and returns
-// the written output as a JSON resource.
+// the written output as a JSON resource. The handler is wrapped with
+// cfg.ResourceMiddlewares, plus a default logging middleware unless
+// cfg.DisableRequestLogging is set.
 func GenResourceHandler(
-	name string, mimeType string,
+	cfg *Config, name string, mimeType string,
 	op func(*mcp.ReadResourceRequest, io.Writer) error,
 ) mcp.ResourceHandler {
 	return func(
 		ctx context.Context, req *mcp.ReadResourceRequest,
 	) (*mcp.ReadResourceResult, error) {
-		logger := slog.New(
-			mcp.NewLoggingHandler(
-				req.Session,
-				&mcp.LoggingHandlerOptions{
-					LoggerName: name, MinInterval: time.Second,
+		core := func(
+			ctx context.Context, req *mcp.ReadResourceRequest,
+		) (*mcp.ReadResourceResult, error) {
+			var writer bytes.Buffer
+			if err := op(req, &writer); err != nil {
+				return nil, err
+			}
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{
+					{URI: req.Params.URI, MIMEType: mimeType, Text: writer.String()},
 				},
-			),
-		)
+			}, nil
+		}
 
-		var writer bytes.Buffer
-		err := op(req, &writer)
-		if err != nil {
-			logger.ErrorContext(ctx, err.Error(), "uri", req.Params.URI)
-			slog.ErrorContext(ctx, err.Error(), "uri", req.Params.URI)
-			return nil, err
+		mws := make([]ResourceMiddleware, 0, len(cfg.ResourceMiddlewares)+1)
+		if !cfg.DisableRequestLogging {
+			mws = append(mws, resourceLoggingMiddleware(cfg, name))
 		}
+		mws = append(mws, cfg.ResourceMiddlewares...)
+
+		return chainResource(core, mws)(ctx, req)
+	}
+}
 
-		logger.InfoContext(ctx, "resource read", "uri", req.Params.URI)
-		slog.InfoContext(
-			ctx, "resource read", "resource", name, "uri", req.Params.URI,
-		)
+// resourceLoggingMiddleware logs each resource read through both the MCP
+// session logger (built via cfg.LoggerFactory, or the default handler) and
+// the global slog logger.
+func resourceLoggingMiddleware(cfg *Config, name string) ResourceMiddleware {
+	return func(next ResourceHandlerFunc) ResourceHandlerFunc {
+		return func(
+			ctx context.Context, req *mcp.ReadResourceRequest,
+		) (*mcp.ReadResourceResult, error) {
+			logger := newSessionLogger(cfg, req.Session, name)
 
-		return &mcp.ReadResourceResult{
-			Contents: []*mcp.ResourceContents{
-				{URI: req.Params.URI, MIMEType: mimeType, Text: writer.String()},
-			},
-		}, nil
+			result, err := next(ctx, req)
+			if err != nil {
+				logger.ErrorContext(ctx, err.Error(), "uri", req.Params.URI)
+				slog.ErrorContext(ctx, err.Error(), "uri", req.Params.URI)
+				return result, err
+			}
+
+			logger.InfoContext(ctx, "resource read", "uri", req.Params.URI)
+			slog.InfoContext(
+				ctx, "resource read", "resource", name, "uri", req.Params.URI,
+			)
+			return result, nil
+		}
 	}
 }
@@ -0,0 +1,280 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	installShort = "Install this server into a client's MCP config"
+	installLong  = "Generate or patch the JSON config file a supported MCP client " +
+		"reads to discover this server"
+	uninstallShort = "Remove this server from a client's MCP config"
+
+	clientUsage     = "claude|cursor|continue|generic"
+	transportUsage  = "stdio|http"
+	configPathUsage = "Override the detected client config path"
+	printUsage      = "Print the mcpServers fragment instead of writing the config file"
+	nameUsage       = "Name to register the server under (defaults to Config.Name)"
+	urlUsage        = "URL of the running server (http transport only)"
+	argUsage        = "Extra argument to pass to the server command (repeatable)"
+)
+
+// mcpServerEntry is the per-client JSON shape stored under the "mcpServers"
+// key. Continue and Cursor both understand this shape for stdio transports;
+// http transports use Url instead of Command/Args.
+type mcpServerEntry struct {
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Url     string   `json:"url,omitempty"`
+}
+
+// clientConfigPath returns the default config file path for a known client,
+// or an error if the client is unrecognized.
+func clientConfigPath(client string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolving home directory: %w", err)
+	}
+
+	switch client {
+	case "claude":
+		switch runtime.GOOS {
+		case "darwin":
+			return filepath.Join(
+				home, "Library", "Application Support",
+				"Claude", "claude_desktop_config.json",
+			), nil
+		case "windows":
+			return filepath.Join(
+				os.Getenv("APPDATA"), "Claude", "claude_desktop_config.json",
+			), nil
+		default:
+			return filepath.Join(
+				home, ".config", "Claude", "claude_desktop_config.json",
+			), nil
+		}
+	case "cursor":
+		return filepath.Join(home, ".cursor", "mcp.json"), nil
+	case "continue":
+		return filepath.Join(home, ".continue", "config.json"), nil
+	case "generic":
+		return filepath.Join(home, ".mcp", "mcp.json"), nil
+	default:
+		return "", fmt.Errorf("unknown client %q (want %s)", client, clientUsage)
+	}
+}
+
+// buildServerEntry assembles the mcpServerEntry for the given transport.
+// For stdio, the generated command line invokes `<command> mcp --mode
+// stdio`, the mcp subcommand newCommand registers, rather than the bare
+// executable (which would run its root command instead of the server).
+func buildServerEntry(
+	transport, url, command string, args []string,
+) (*mcpServerEntry, error) {
+	switch transport {
+	case "stdio":
+		serverArgs := append([]string{mcpUse, "--mode", "stdio"}, args...)
+		return &mcpServerEntry{Command: command, Args: serverArgs}, nil
+	case "http":
+		if url == "" {
+			return nil, fmt.Errorf("--url is required for http transport")
+		}
+		return &mcpServerEntry{Url: url}, nil
+	default:
+		return nil, fmt.Errorf("unknown transport %q (want %s)", transport, transportUsage)
+	}
+}
+
+// loadConfig reads an existing client config file into a generic map,
+// returning an empty map if the file does not exist yet.
+func loadConfig(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	cfg := map[string]any{}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// backupConfig copies the existing file at path to path+".bak", if it exists.
+func backupConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	return os.WriteFile(path+".bak", data, 0o644)
+}
+
+// writeConfig backs up the prior file, then writes cfg to path, creating
+// parent directories as needed.
+func writeConfig(path string, cfg map[string]any) error {
+	if err := backupConfig(path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", path, err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func newInstallCommand(cfg *Config) *cobra.Command {
+	var (
+		client     string
+		name       string
+		transport  string
+		url        string
+		args       []string
+		configPath string
+		print      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: installShort,
+		Long:  installLong,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if name == "" {
+				name = cfg.Name
+			}
+
+			command, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("resolving executable path: %w", err)
+			}
+
+			entry, err := buildServerEntry(transport, url, command, args)
+			if err != nil {
+				return err
+			}
+
+			if print {
+				fragment, err := json.MarshalIndent(
+					map[string]*mcpServerEntry{name: entry}, "", "  ",
+				)
+				if err != nil {
+					return fmt.Errorf("marshaling fragment: %w", err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(fragment))
+				return nil
+			}
+
+			if configPath == "" {
+				configPath, err = clientConfigPath(client)
+				if err != nil {
+					return err
+				}
+			}
+
+			fileCfg, err := loadConfig(configPath)
+			if err != nil {
+				return err
+			}
+
+			servers, _ := fileCfg["mcpServers"].(map[string]any)
+			if servers == nil {
+				servers = map[string]any{}
+			}
+			servers[name] = entry
+			fileCfg["mcpServers"] = servers
+
+			if err = writeConfig(configPath, fileCfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(
+				cmd.OutOrStdout(), "installed %q into %s\n", name, configPath,
+			)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&client, "client", "claude", clientUsage)
+	cmd.Flags().StringVar(&name, "name", "", nameUsage)
+	cmd.Flags().StringVar(&transport, "transport", "stdio", transportUsage)
+	cmd.Flags().StringVar(&url, "url", "", urlUsage)
+	cmd.Flags().StringArrayVar(&args, "arg", nil, argUsage)
+	cmd.Flags().StringVar(&configPath, "config-path", "", configPathUsage)
+	cmd.Flags().BoolVar(&print, "print", false, printUsage)
+
+	return cmd
+}
+
+func newUninstallCommand(cfg *Config) *cobra.Command {
+	var (
+		client     string
+		name       string
+		configPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: uninstallShort,
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if name == "" {
+				name = cfg.Name
+			}
+
+			var err error
+			if configPath == "" {
+				configPath, err = clientConfigPath(client)
+				if err != nil {
+					return err
+				}
+			}
+
+			fileCfg, err := loadConfig(configPath)
+			if err != nil {
+				return err
+			}
+
+			servers, _ := fileCfg["mcpServers"].(map[string]any)
+			if _, ok := servers[name]; !ok {
+				fmt.Fprintf(
+					cmd.OutOrStdout(), "%q not found in %s\n", name, configPath,
+				)
+				return nil
+			}
+			delete(servers, name)
+			fileCfg["mcpServers"] = servers
+
+			if err = writeConfig(configPath, fileCfg); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(
+				cmd.OutOrStdout(), "uninstalled %q from %s\n", name, configPath,
+			)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&client, "client", "claude", clientUsage)
+	cmd.Flags().StringVar(&name, "name", "", nameUsage)
+	cmd.Flags().StringVar(&configPath, "config-path", "", configPathUsage)
+
+	return cmd
+}
@@ -0,0 +1,190 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildServerEntry(t *testing.T) {
+	entry, err := buildServerEntry("stdio", "", "/usr/local/bin/yutu", []string{"--verbose"})
+	if err != nil {
+		t.Fatalf("stdio transport should succeed, got %v", err)
+	}
+	if entry.Command != "/usr/local/bin/yutu" {
+		t.Fatalf("Command = %q, want the executable path", entry.Command)
+	}
+	wantArgs := []string{mcpUse, "--mode", "stdio", "--verbose"}
+	if len(entry.Args) != len(wantArgs) {
+		t.Fatalf("Args = %v, want %v", entry.Args, wantArgs)
+	}
+	for i, a := range wantArgs {
+		if entry.Args[i] != a {
+			t.Fatalf("Args = %v, want %v", entry.Args, wantArgs)
+		}
+	}
+	if entry.Url != "" {
+		t.Fatalf("stdio entry should not set Url, got %q", entry.Url)
+	}
+
+	entry, err = buildServerEntry("http", "http://localhost:8216/mcp", "yutu", nil)
+	if err != nil {
+		t.Fatalf("http transport with --url should succeed, got %v", err)
+	}
+	if entry.Url != "http://localhost:8216/mcp" || entry.Command != "" {
+		t.Fatalf("http entry = %+v, want Url set and Command empty", entry)
+	}
+
+	if _, err = buildServerEntry("http", "", "yutu", nil); err == nil {
+		t.Fatal("http transport without --url should fail")
+	}
+
+	if _, err = buildServerEntry("carrier-pigeon", "", "yutu", nil); err == nil {
+		t.Fatal("unknown transport should fail")
+	}
+}
+
+func TestInstallMergesWithoutClobberingUnrelatedKeys(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	existing := map[string]any{
+		"theme": "dark",
+		"mcpServers": map[string]any{
+			"other-server": map[string]any{"command": "other", "args": []string{}},
+		},
+	}
+	data, _ := json.Marshal(existing)
+	if err := os.WriteFile(configPath, data, 0o644); err != nil {
+		t.Fatalf("writing seed config: %v", err)
+	}
+
+	cfg := &Config{Name: "yutu"}
+	install := newInstallCommand(cfg)
+	install.SetOut(&bytes.Buffer{})
+	install.SetArgs([]string{
+		"--config-path", configPath,
+		"--name", "yutu",
+		"--transport", "http",
+		"--url", "http://localhost:8216/mcp",
+	})
+	if err := install.Execute(); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	out, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("reading written config: %v", err)
+	}
+	var got map[string]any
+	if err = json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("parsing written config: %v", err)
+	}
+
+	if got["theme"] != "dark" {
+		t.Fatalf("unrelated key %q was clobbered, got %v", "theme", got["theme"])
+	}
+	servers, ok := got["mcpServers"].(map[string]any)
+	if !ok {
+		t.Fatalf("mcpServers missing or wrong shape: %v", got["mcpServers"])
+	}
+	if _, ok = servers["other-server"]; !ok {
+		t.Fatal("pre-existing mcpServers entry was clobbered")
+	}
+	if _, ok = servers["yutu"]; !ok {
+		t.Fatal("new mcpServers entry was not written")
+	}
+
+	if _, err = os.Stat(configPath + ".bak"); err != nil {
+		t.Fatalf("expected a backup of the prior config, got %v", err)
+	}
+}
+
+func TestInstallUninstallRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+
+	cfg := &Config{Name: "yutu"}
+
+	install := newInstallCommand(cfg)
+	install.SetOut(&bytes.Buffer{})
+	install.SetArgs([]string{
+		"--config-path", configPath,
+		"--name", "yutu",
+		"--transport", "stdio",
+	})
+	if err := install.Execute(); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+
+	servers := readMCPServers(t, configPath)
+	if _, ok := servers["yutu"]; !ok {
+		t.Fatal("install did not write the mcpServers entry")
+	}
+
+	uninstall := newUninstallCommand(cfg)
+	uninstall.SetOut(&bytes.Buffer{})
+	uninstall.SetArgs([]string{
+		"--config-path", configPath,
+		"--name", "yutu",
+	})
+	if err := uninstall.Execute(); err != nil {
+		t.Fatalf("uninstall: %v", err)
+	}
+
+	servers = readMCPServers(t, configPath)
+	if _, ok := servers["yutu"]; ok {
+		t.Fatal("uninstall did not remove the mcpServers entry")
+	}
+}
+
+func readMCPServers(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v", path, err)
+	}
+	var cfg map[string]any
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("parsing %s: %v", path, err)
+	}
+	servers, _ := cfg["mcpServers"].(map[string]any)
+	return servers
+}
+
+func TestInstallPrintDoesNotWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "does-not-exist", "config.json")
+
+	cfg := &Config{Name: "yutu"}
+	install := newInstallCommand(cfg)
+	var out bytes.Buffer
+	install.SetOut(&out)
+	install.SetArgs([]string{
+		"--print",
+		"--config-path", configPath,
+		"--name", "yutu",
+		"--transport", "http",
+		"--url", "http://localhost:8216/mcp",
+	})
+	if err := install.Execute(); err != nil {
+		t.Fatalf("install --print: %v", err)
+	}
+
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Fatalf("--print must not write the config file, stat err = %v", err)
+	}
+
+	var fragment map[string]mcpServerEntry
+	if err := json.Unmarshal(out.Bytes(), &fragment); err != nil {
+		t.Fatalf("--print output is not valid JSON: %v, output: %s", err, out.String())
+	}
+	entry, ok := fragment["yutu"]
+	if !ok || entry.Url != "http://localhost:8216/mcp" {
+		t.Fatalf("--print output = %s, want the yutu entry with the given url", out.String())
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+// Package metrics provides the Prometheus collectors backing cobra-mcp's
+// observability subsystem.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the Prometheus collectors registered for a single server
+// instance.
+type Metrics struct {
+	registry       *prometheus.Registry
+	toolCalls      *prometheus.CounterVec
+	toolDuration   *prometheus.HistogramVec
+	activeSessions prometheus.Gauge
+}
+
+// New creates the cobramcp_* collectors, each carrying a constant "server"
+// label set to namespace (typically Config.Name), and registers them on a
+// fresh registry.
+func New(namespace string) *Metrics {
+	reg := prometheus.NewRegistry()
+
+	m := &Metrics{
+		registry: reg,
+		toolCalls: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "cobramcp",
+				Name:      "tool_calls_total",
+				Help:      "Total number of MCP tool calls, by tool and status.",
+				ConstLabels: prometheus.Labels{
+					"server": namespace,
+				},
+			},
+			[]string{"tool", "status"},
+		),
+		toolDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "cobramcp",
+				Name:      "tool_call_duration_seconds",
+				Help:      "Duration of MCP tool calls, by tool.",
+				ConstLabels: prometheus.Labels{
+					"server": namespace,
+				},
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"tool"},
+		),
+		activeSessions: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: "cobramcp",
+				Name:      "active_sessions",
+				Help:      "Number of currently connected MCP sessions.",
+				ConstLabels: prometheus.Labels{
+					"server": namespace,
+				},
+			},
+		),
+	}
+
+	reg.MustRegister(m.toolCalls, m.toolDuration, m.activeSessions)
+	return m
+}
+
+// RecordToolCall implements cobramcp.ToolMetricsRecorder.
+func (m *Metrics) RecordToolCall(tool, status string, duration time.Duration) {
+	m.toolCalls.WithLabelValues(tool, status).Inc()
+	m.toolDuration.WithLabelValues(tool).Observe(duration.Seconds())
+}
+
+// SetActiveSessions sets the active session gauge to n. Callers should
+// invoke it with the current count of live MCP sessions, e.g. on a poll
+// interval against the transport's session registry.
+func (m *Metrics) SetActiveSessions(n int) {
+	m.activeSessions.Set(float64(n))
+}
+
+// Handler returns the /metrics HTTP handler for this registry.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,476 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest describes a set of MCP tools to register declaratively, letting
+// applications describe tools in YAML or JSON instead of wiring each one up
+// in Go.
+type Manifest struct {
+	Tools []ManifestTool `yaml:"tools" json:"tools"`
+}
+
+// ManifestTool is a single declarative tool entry. Exactly one of Func or
+// Command must be set.
+type ManifestTool struct {
+	// Name is the tool's registered name.
+	Name string `yaml:"name" json:"name"`
+
+	// Description is shown to MCP clients.
+	Description string `yaml:"description" json:"description"`
+
+	// InputSchema is the JSON Schema describing the tool's input.
+	InputSchema map[string]any `yaml:"inputSchema,omitempty" json:"inputSchema,omitempty"`
+
+	// Func names an in-process function registered with
+	// RegisterManifestFunc.
+	Func string `yaml:"func,omitempty" json:"func,omitempty"`
+
+	// Command is an external subprocess argv template executed for this
+	// tool, with "{{.key}}" placeholders substituted from the tool input,
+	// e.g. ["mytool", "--id={{.id}}"].
+	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
+
+	// Timeout bounds how long the tool call may run. Zero means no
+	// additional timeout beyond the request context.
+	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Middlewares names middlewares, registered with
+	// RegisterManifestMiddleware, to wrap this tool with, in order.
+	Middlewares []string `yaml:"middlewares,omitempty" json:"middlewares,omitempty"`
+}
+
+// manifestToolAlias has ManifestTool's fields without its MarshalJSON and
+// UnmarshalJSON methods, so they can delegate to encoding/json's default
+// struct handling without recursing.
+type manifestToolAlias ManifestTool
+
+// MarshalJSON serializes Timeout as a duration string (e.g. "5s") instead of
+// encoding/json's default raw nanosecond count, matching how yaml.v3 already
+// encodes a time.Duration field. LoadManifest parses both YAML and JSON
+// manifests through yaml.Unmarshal, which only accepts a duration as a
+// string; a bare integer fails to parse, breaking `mcp dump-manifest
+// --format json` as an input to `mcp validate-manifest` whenever Timeout is
+// set.
+func (t ManifestTool) MarshalJSON() ([]byte, error) {
+	aux := struct {
+		manifestToolAlias
+		Timeout string `json:"timeout,omitempty"`
+	}{manifestToolAlias: manifestToolAlias(t)}
+	if t.Timeout != 0 {
+		aux.Timeout = t.Timeout.String()
+	}
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON parses Timeout from a duration string, the counterpart to
+// MarshalJSON.
+func (t *ManifestTool) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*manifestToolAlias
+		Timeout string `json:"timeout,omitempty"`
+	}{manifestToolAlias: (*manifestToolAlias)(t)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if aux.Timeout == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(aux.Timeout)
+	if err != nil {
+		return fmt.Errorf("parsing timeout %q: %w", aux.Timeout, err)
+	}
+	t.Timeout = d
+	return nil
+}
+
+// ManifestFunc is the in-process implementation a manifest entry's Func
+// name resolves to.
+type ManifestFunc func(input map[string]any, w io.Writer) error
+
+var manifestFuncs = map[string]ManifestFunc{}
+
+// RegisterManifestFunc makes fn available to manifest entries under name
+// via their "func" field. Call it before ServerAndCommand so manifest
+// loading can resolve it.
+func RegisterManifestFunc(name string, fn ManifestFunc) {
+	manifestFuncs[name] = fn
+}
+
+var manifestMiddlewares = map[string]ToolMiddleware{}
+
+// RegisterManifestMiddleware makes mw available to manifest entries under
+// name via their "middlewares" field.
+func RegisterManifestMiddleware(name string, mw ToolMiddleware) {
+	manifestMiddlewares[name] = mw
+}
+
+// LoadManifest parses a YAML (or JSON, a YAML subset) manifest from r.
+func LoadManifest(r io.Reader) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	var m Manifest
+	if err = yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// validate checks that t is a well-formed entry, without resolving Func or
+// Middlewares against the process's registries.
+func (t *ManifestTool) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("manifest tool missing name")
+	}
+	if (t.Func == "") == (len(t.Command) == 0) {
+		return fmt.Errorf("manifest tool %q: set exactly one of func or command", t.Name)
+	}
+	if err := validateSchema(t.InputSchema); err != nil {
+		return fmt.Errorf("manifest tool %q: inputSchema: %w", t.Name, err)
+	}
+	return nil
+}
+
+// jsonSchemaTypes are the type keyword values defined by JSON Schema.
+var jsonSchemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true,
+	"number": true, "integer": true, "boolean": true, "null": true,
+}
+
+// validateSchemaType checks a JSON Schema "type" keyword value, which may be
+// either a single type string or (per the spec, for a nullable field such as
+// ["string", "null"]) an array of type strings.
+func validateSchemaType(rawType any) error {
+	switch typ := rawType.(type) {
+	case string:
+		if !jsonSchemaTypes[typ] {
+			return fmt.Errorf("type %v is not a valid JSON Schema type", rawType)
+		}
+	case []any:
+		for _, rawElem := range typ {
+			elem, ok := rawElem.(string)
+			if !ok || !jsonSchemaTypes[elem] {
+				return fmt.Errorf("type %v is not a valid JSON Schema type", rawType)
+			}
+		}
+	default:
+		return fmt.Errorf("type %v is not a valid JSON Schema type", rawType)
+	}
+	return nil
+}
+
+// validateSchema performs a structural sanity check of schema as a JSON
+// Schema object: it does not implement the full JSON Schema specification,
+// but it catches the mistakes most likely in a hand-written manifest, such
+// as a misspelled "type" or a "properties"/"required" of the wrong shape.
+func validateSchema(schema map[string]any) error {
+	if schema == nil {
+		return nil
+	}
+
+	if rawType, ok := schema["type"]; ok {
+		if err := validateSchemaType(rawType); err != nil {
+			return err
+		}
+	}
+
+	var propNames map[string]bool
+	if rawProps, ok := schema["properties"]; ok {
+		props, ok := rawProps.(map[string]any)
+		if !ok {
+			return fmt.Errorf("properties must be an object")
+		}
+		propNames = make(map[string]bool, len(props))
+		for name, rawPropSchema := range props {
+			propSchema, ok := rawPropSchema.(map[string]any)
+			if !ok {
+				return fmt.Errorf("properties.%s must be an object", name)
+			}
+			if err := validateSchema(propSchema); err != nil {
+				return fmt.Errorf("properties.%s: %w", name, err)
+			}
+			propNames[name] = true
+		}
+	}
+
+	if rawRequired, ok := schema["required"]; ok {
+		required, ok := rawRequired.([]any)
+		if !ok {
+			return fmt.Errorf("required must be an array of strings")
+		}
+		for _, rawName := range required {
+			name, ok := rawName.(string)
+			if !ok {
+				return fmt.Errorf("required entries must be strings")
+			}
+			if propNames != nil && !propNames[name] {
+				return fmt.Errorf("required %q is not listed in properties", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// op builds the func(map[string]any, io.Writer) error for t, either
+// invoking its registered Func or executing its Command as a subprocess
+// with argv templating over the tool input.
+func (t *ManifestTool) op() (func(map[string]any, io.Writer) error, error) {
+	if t.Func != "" {
+		fn, ok := manifestFuncs[t.Func]
+		if !ok {
+			return nil, fmt.Errorf("manifest tool %q: unregistered func %q", t.Name, t.Func)
+		}
+		return fn, nil
+	}
+
+	command := t.Command
+	return func(input map[string]any, w io.Writer) error {
+		argv := make([]string, len(command))
+		for i, arg := range command {
+			argv[i] = expandArgTemplate(arg, input)
+		}
+		cmd := exec.Command(argv[0], argv[1:]...)
+
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+
+		if err := cmd.Run(); err != nil {
+			return NewToolError(
+				"subprocess_failed",
+				fmt.Sprintf("%s: %s", err, output.String()),
+				map[string]any{"command": argv},
+			)
+		}
+		_, err := w.Write(output.Bytes())
+		return err
+	}, nil
+}
+
+// expandArgTemplate replaces "{{.key}}" placeholders in arg with the string
+// form of input[key].
+func expandArgTemplate(arg string, input map[string]any) string {
+	for key, value := range input {
+		arg = strings.ReplaceAll(arg, fmt.Sprintf("{{.%s}}", key), fmt.Sprintf("%v", value))
+	}
+	return arg
+}
+
+// RegisterManifest loads the manifest at cfg.Manifest, if set, and
+// registers each entry as a tool on server via RegisterManifestTool.
+func RegisterManifest(cfg *Config, server *mcp.Server) error {
+	if cfg.Manifest == "" {
+		return nil
+	}
+
+	f, err := os.Open(cfg.Manifest)
+	if err != nil {
+		return fmt.Errorf("opening manifest %s: %w", cfg.Manifest, err)
+	}
+	defer f.Close()
+
+	m, err := LoadManifest(f)
+	if err != nil {
+		return err
+	}
+
+	for _, tool := range m.Tools {
+		if err = RegisterManifestTool(cfg, server, tool); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterManifestTool registers a single manifest entry as a tool on
+// server, backed by GenToolHandler, and records it on cfg so that
+// `mcp dump-manifest` can serialize it back out.
+func RegisterManifestTool(cfg *Config, server *mcp.Server, tool ManifestTool) error {
+	if err := tool.validate(); err != nil {
+		return err
+	}
+
+	op, err := tool.op()
+	if err != nil {
+		return err
+	}
+
+	toolCfg := *cfg
+	toolCfg.ToolMiddlewares = append([]ToolMiddleware(nil), cfg.ToolMiddlewares...)
+	for _, name := range tool.Middlewares {
+		mw, ok := manifestMiddlewares[name]
+		if !ok {
+			return fmt.Errorf("manifest tool %q: unregistered middleware %q", tool.Name, name)
+		}
+		toolCfg.WithToolMiddleware(mw)
+	}
+	if tool.Timeout > 0 {
+		toolCfg.WithToolMiddleware(TimeoutMiddleware(tool.Timeout))
+	}
+
+	mcpTool := &mcp.Tool{Name: tool.Name, Description: tool.Description}
+	if tool.InputSchema != nil {
+		// tool.InputSchema is a typed map[string]any; assigning a nil one to
+		// mcp.Tool.InputSchema (an any) would store a non-nil interface
+		// wrapping a nil map, which mcp.AddTool treats as an explicitly
+		// provided (but empty) schema instead of inferring one, and panics.
+		mcpTool.InputSchema = tool.InputSchema
+	}
+
+	mcp.AddTool(server, mcpTool, GenToolHandler(&toolCfg, tool.Name, op))
+
+	cfg.registeredTools = append(cfg.registeredTools, tool)
+	return nil
+}
+
+// AddTool registers a tool built from op via GenToolHandler, exactly like
+// calling mcp.AddTool(server, tool, GenToolHandler(cfg, tool.Name, op))
+// directly, but also records tool under manifestFuncs and cfg.registeredTools
+// so that `mcp dump-manifest` can see it. Prefer this over mcp.AddTool when
+// some tools are wired up in Go and others will eventually move to a
+// manifest; it lets dump-manifest bootstrap one from the other.
+func AddTool[T any](
+	cfg *Config, server *mcp.Server, tool *mcp.Tool, op func(T, io.Writer) error,
+) {
+	mcp.AddTool(server, tool, GenToolHandler(cfg, tool.Name, op))
+
+	RegisterManifestFunc(tool.Name, func(input map[string]any, w io.Writer) error {
+		data, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("marshaling input for %q: %w", tool.Name, err)
+		}
+		var typed T
+		if err = json.Unmarshal(data, &typed); err != nil {
+			return fmt.Errorf("unmarshaling input for %q: %w", tool.Name, err)
+		}
+		return op(typed, w)
+	})
+
+	cfg.registeredTools = append(cfg.registeredTools, ManifestTool{
+		Name:        tool.Name,
+		Description: tool.Description,
+		InputSchema: schemaToMap(tool.InputSchema),
+		Func:        tool.Name,
+	})
+}
+
+// schemaToMap converts an *mcp.Tool's InputSchema, which may be a
+// map[string]any (as manifest-sourced tools set it) or a *jsonschema.Schema
+// (as tools built with the SDK's schema inference commonly set it), into the
+// map[string]any ManifestTool stores, for round-tripping through
+// `mcp dump-manifest`. Any other value goes through the same JSON round
+// trip; one that does not marshal to a JSON object yields nil rather than a
+// dump-manifest entry with a broken schema.
+func schemaToMap(schema any) map[string]any {
+	switch schema := schema.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		return schema
+	default:
+		data, err := json.Marshal(schema)
+		if err != nil {
+			return nil
+		}
+		var m map[string]any
+		if err = json.Unmarshal(data, &m); err != nil {
+			return nil
+		}
+		return m
+	}
+}
+
+func newValidateManifestCommand(cfg *Config) *cobra.Command {
+	var manifestPath string
+
+	cmd := &cobra.Command{
+		Use:   "validate-manifest",
+		Short: "Validate a manifest file and print its tool list",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := manifestPath
+			if path == "" {
+				path = cfg.Manifest
+			}
+			if path == "" {
+				return fmt.Errorf("no manifest path given; set --manifest or Config.Manifest")
+			}
+
+			f, err := os.Open(path)
+			if err != nil {
+				return fmt.Errorf("opening manifest %s: %w", path, err)
+			}
+			defer f.Close()
+
+			m, err := LoadManifest(f)
+			if err != nil {
+				return err
+			}
+
+			for _, tool := range m.Tools {
+				if err = tool.validate(); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", tool.Name, tool.Description)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifestPath, "manifest", "", "Path to the manifest file (defaults to Config.Manifest)")
+	return cmd
+}
+
+func newDumpManifestCommand(cfg *Config) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "dump-manifest",
+		Short: "Print the tools registered through the manifest subsystem or AddTool as a manifest",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m := &Manifest{Tools: cfg.registeredTools}
+
+			var (
+				data []byte
+				err  error
+			)
+			switch format {
+			case "yaml":
+				data, err = yaml.Marshal(m)
+			case "json":
+				data, err = json.MarshalIndent(m, "", "  ")
+			default:
+				return fmt.Errorf("unknown format %q (want yaml|json)", format)
+			}
+			if err != nil {
+				return fmt.Errorf("marshaling manifest: %w", err)
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "yaml", "Output format: yaml|json")
+	return cmd
+}
@@ -0,0 +1,267 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestValidateSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  map[string]any
+		wantErr bool
+	}{
+		{"nil schema", nil, false},
+		{
+			"valid single type", map[string]any{"type": "string"}, false,
+		},
+		{
+			"invalid single type", map[string]any{"type": "stringg"}, true,
+		},
+		{
+			"valid array type (nullable field)",
+			map[string]any{"type": []any{"string", "null"}}, false,
+		},
+		{
+			"invalid array type", map[string]any{"type": []any{"string", "not-a-type"}}, true,
+		},
+		{
+			"array type with non-string element",
+			map[string]any{"type": []any{"string", 1}}, true,
+		},
+		{
+			"type of the wrong shape", map[string]any{"type": 1}, true,
+		},
+		{
+			"valid properties",
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+				},
+			},
+			false,
+		},
+		{
+			"properties of the wrong shape",
+			map[string]any{"properties": "not-an-object"}, true,
+		},
+		{
+			"property schema of the wrong shape",
+			map[string]any{
+				"properties": map[string]any{"name": "not-an-object"},
+			},
+			true,
+		},
+		{
+			"required referencing a declared property",
+			map[string]any{
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+				},
+				"required": []any{"name"},
+			},
+			false,
+		},
+		{
+			"required not in properties",
+			map[string]any{
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string"},
+				},
+				"required": []any{"missing"},
+			},
+			true,
+		},
+		{
+			"required of the wrong shape",
+			map[string]any{"required": "name"}, true,
+		},
+		{
+			"required entry of the wrong shape",
+			map[string]any{"required": []any{1}}, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchema(tt.schema)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateSchema(%v) = %v, wantErr %v", tt.schema, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadManifestYAML(t *testing.T) {
+	yamlDoc := `
+tools:
+  - name: greet
+    description: Say hello
+    command: ["echo", "hello {{.name}}"]
+    timeout: 5s
+`
+	m, err := LoadManifest(strings.NewReader(yamlDoc))
+	if err != nil {
+		t.Fatalf("LoadManifest(yaml): %v", err)
+	}
+	if len(m.Tools) != 1 {
+		t.Fatalf("len(m.Tools) = %d, want 1", len(m.Tools))
+	}
+	tool := m.Tools[0]
+	if tool.Name != "greet" || tool.Description != "Say hello" {
+		t.Fatalf("tool = %+v, want name=greet description=%q", tool, "Say hello")
+	}
+	if len(tool.Command) != 2 || tool.Command[0] != "echo" {
+		t.Fatalf("tool.Command = %v, want [echo ...]", tool.Command)
+	}
+}
+
+func TestLoadManifestJSON(t *testing.T) {
+	jsonDoc := `{
+		"tools": [
+			{
+				"name": "greet",
+				"description": "Say hello",
+				"func": "greetFunc"
+			}
+		]
+	}`
+	m, err := LoadManifest(strings.NewReader(jsonDoc))
+	if err != nil {
+		t.Fatalf("LoadManifest(json): %v", err)
+	}
+	if len(m.Tools) != 1 {
+		t.Fatalf("len(m.Tools) = %d, want 1", len(m.Tools))
+	}
+	tool := m.Tools[0]
+	if tool.Name != "greet" || tool.Func != "greetFunc" {
+		t.Fatalf("tool = %+v, want name=greet func=greetFunc", tool)
+	}
+}
+
+func TestExpandArgTemplate(t *testing.T) {
+	input := map[string]any{"id": 42, "name": "alice"}
+
+	got := expandArgTemplate("--id={{.id}}", input)
+	if got != "--id=42" {
+		t.Fatalf("expandArgTemplate = %q, want --id=42", got)
+	}
+
+	got = expandArgTemplate("{{.name}}-{{.id}}", input)
+	if got != "alice-42" {
+		t.Fatalf("expandArgTemplate = %q, want alice-42", got)
+	}
+
+	got = expandArgTemplate("--missing={{.missing}}", input)
+	if got != "--missing={{.missing}}" {
+		t.Fatalf("expandArgTemplate left unmatched placeholder unexpectedly changed: %q", got)
+	}
+}
+
+func TestRegisterManifestToolWithoutInputSchema(t *testing.T) {
+	cfg := &Config{Name: "test", Version: "v0.0.0"}
+	server := newServer(cfg)
+
+	RegisterManifestFunc("test-no-input-schema", func(map[string]any, io.Writer) error {
+		return nil
+	})
+
+	// A manifest entry with no inputSchema is the natural way to write a
+	// no-args (or auto-inferred) tool; it must not panic inside mcp.AddTool.
+	tool := ManifestTool{Name: "slow", Func: "test-no-input-schema"}
+	if err := RegisterManifestTool(cfg, server, tool); err != nil {
+		t.Fatalf("RegisterManifestTool: %v", err)
+	}
+}
+
+func TestRegisterManifestToolDumpManifestRoundTrip(t *testing.T) {
+	cfg := &Config{Name: "test", Version: "v0.0.0"}
+	server := newServer(cfg)
+
+	RegisterManifestFunc("test-register-manifest-tool", func(map[string]any, io.Writer) error {
+		return nil
+	})
+
+	tool := ManifestTool{
+		Name:        "greet",
+		Description: "Say hello",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"name": map[string]any{"type": "string"},
+			},
+		},
+		Func:    "test-register-manifest-tool",
+		Timeout: 5 * time.Second,
+	}
+	if err := RegisterManifestTool(cfg, server, tool); err != nil {
+		t.Fatalf("RegisterManifestTool: %v", err)
+	}
+
+	cmd := newDumpManifestCommand(cfg)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("dump-manifest: %v", err)
+	}
+
+	dumped, err := LoadManifest(&out)
+	if err != nil {
+		t.Fatalf("LoadManifest(dump-manifest output): %v, output: %s", err, out.String())
+	}
+	if len(dumped.Tools) != 1 {
+		t.Fatalf("len(dumped.Tools) = %d, want 1, output: %s", len(dumped.Tools), out.String())
+	}
+	got := dumped.Tools[0]
+	if got.Name != tool.Name || got.Description != tool.Description {
+		t.Fatalf("round-tripped tool = %+v, want name=%q description=%q", got, tool.Name, tool.Description)
+	}
+	if got.Timeout != tool.Timeout {
+		t.Fatalf("round-tripped Timeout = %v, want %v (output: %s)", got.Timeout, tool.Timeout, out.String())
+	}
+	if err = got.validate(); err != nil {
+		t.Fatalf("round-tripped tool failed validation: %v", err)
+	}
+}
+
+func TestAddToolDumpManifestRoundTrip(t *testing.T) {
+	cfg := &Config{Name: "test", Version: "v0.0.0"}
+	server := newServer(cfg)
+
+	type waveInput struct {
+		Name string `json:"name"`
+	}
+	AddTool(cfg, server, &mcp.Tool{Name: "wave", Description: "Wave hello"}, func(waveInput, io.Writer) error {
+		return nil
+	})
+
+	cmd := newDumpManifestCommand(cfg)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("dump-manifest: %v", err)
+	}
+
+	dumped, err := LoadManifest(&out)
+	if err != nil {
+		t.Fatalf("LoadManifest(dump-manifest output): %v, output: %s", err, out.String())
+	}
+	if len(dumped.Tools) != 1 {
+		t.Fatalf("len(dumped.Tools) = %d, want 1, output: %s", len(dumped.Tools), out.String())
+	}
+	got := dumped.Tools[0]
+	if got.Name != "wave" || got.Func != "wave" {
+		t.Fatalf("round-tripped tool = %+v, want name=wave func=wave", got)
+	}
+}
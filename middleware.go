@@ -0,0 +1,357 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolHandlerFunc is the type-erased core of a tool call: given the
+// request, produce a result. GenToolHandler builds the innermost
+// ToolHandlerFunc from the caller's op and wraps it with the configured
+// ToolMiddlewares.
+type ToolHandlerFunc func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, any, error)
+
+// ToolMiddleware wraps a ToolHandlerFunc to add cross-cutting behavior such
+// as timeouts, rate limiting, panic recovery, tracing, or auth checks.
+type ToolMiddleware func(next ToolHandlerFunc) ToolHandlerFunc
+
+// ResourceHandlerFunc is the type-erased core of a resource read.
+type ResourceHandlerFunc func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error)
+
+// ResourceMiddleware wraps a ResourceHandlerFunc.
+type ResourceMiddleware func(next ResourceHandlerFunc) ResourceHandlerFunc
+
+// WithToolMiddleware appends mw to cfg.ToolMiddlewares and returns cfg so
+// calls can be chained.
+func (cfg *Config) WithToolMiddleware(mw ...ToolMiddleware) *Config {
+	cfg.ToolMiddlewares = append(cfg.ToolMiddlewares, mw...)
+	return cfg
+}
+
+// WithResourceMiddleware appends mw to cfg.ResourceMiddlewares and returns
+// cfg so calls can be chained.
+func (cfg *Config) WithResourceMiddleware(mw ...ResourceMiddleware) *Config {
+	cfg.ResourceMiddlewares = append(cfg.ResourceMiddlewares, mw...)
+	return cfg
+}
+
+// chainTool wraps core with mws, outermost first.
+func chainTool(core ToolHandlerFunc, mws []ToolMiddleware) ToolHandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		core = mws[i](core)
+	}
+	return core
+}
+
+// chainResource wraps core with mws, outermost first.
+func chainResource(core ResourceHandlerFunc, mws []ResourceMiddleware) ResourceHandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		core = mws[i](core)
+	}
+	return core
+}
+
+// toolCallKey is the context key GenToolHandler uses to thread the tool
+// name and marshaled input through the middleware chain, so middlewares
+// like LoggingMiddleware don't need to be generic over T.
+type toolCallKey struct{}
+
+type toolCall struct {
+	name      string
+	inputJSON string
+}
+
+func withToolCall(ctx context.Context, call *toolCall) context.Context {
+	return context.WithValue(ctx, toolCallKey{}, call)
+}
+
+func toolCallFromContext(ctx context.Context) *toolCall {
+	call, _ := ctx.Value(toolCallKey{}).(*toolCall)
+	return call
+}
+
+// defaultInputLogCap bounds how many bytes of a tool's JSON input are
+// logged when Config.LogOutputs is set and Config.InputLogCap is zero.
+const defaultInputLogCap = 2048
+
+// newSessionLogger builds the per-call logger for session, using
+// cfg.LoggerFactory if set, or the default MCP session logging handler
+// (deduped to one message per second per logger name) otherwise.
+func newSessionLogger(cfg *Config, session *mcp.ServerSession, name string) *slog.Logger {
+	if cfg.LoggerFactory != nil {
+		return cfg.LoggerFactory(session, name)
+	}
+	return slog.New(
+		mcp.NewLoggingHandler(
+			session,
+			&mcp.LoggingHandlerOptions{LoggerName: name, MinInterval: time.Second},
+		),
+	)
+}
+
+// redactedInput applies cfg.RedactInput (if set) and caps the result to
+// cfg.InputLogCap (or defaultInputLogCap) bytes.
+func redactedInput(cfg *Config, inputJSON string) string {
+	if cfg.RedactInput != nil {
+		inputJSON = cfg.RedactInput(inputJSON)
+	}
+
+	capBytes := cfg.InputLogCap
+	if capBytes == 0 {
+		capBytes = defaultInputLogCap
+	}
+	if len(inputJSON) > capBytes {
+		inputJSON = inputJSON[:capBytes] + "...(truncated)"
+	}
+	return inputJSON
+}
+
+// errMessage extracts a human-readable message from either a Go error or
+// an error CallToolResult's text content.
+func errMessage(err error, result *mcp.CallToolResult) string {
+	if err != nil {
+		return err.Error()
+	}
+	if result != nil && len(result.Content) > 0 {
+		if text, ok := result.Content[0].(*mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return "tool call failed"
+}
+
+// toolErrorLogFields extracts the "code" and "fields" a ToolError attached
+// to result.Meta (see GenToolHandler), so LoggingMiddleware's structured
+// log line carries the same diagnostics the client received.
+func toolErrorLogFields(result *mcp.CallToolResult) []any {
+	if result == nil || result.Meta == nil {
+		return nil
+	}
+
+	var logFields []any
+	if code, ok := result.Meta["code"]; ok {
+		logFields = append(logFields, "code", code)
+	}
+	if fields, ok := result.Meta["fields"]; ok {
+		logFields = append(logFields, "fields", fields)
+	}
+	return logFields
+}
+
+// LoggingMiddleware logs each tool call through both the MCP session
+// logger (built via cfg.LoggerFactory, or the default, deduped handler)
+// and the global slog logger. Input and output_length are included only
+// when cfg.LogOutputs is set, subject to cfg.RedactInput and
+// cfg.InputLogCap. GenToolHandler installs this by default; set
+// Config.DisableRequestLogging to omit it.
+func LoggingMiddleware(cfg *Config) ToolMiddleware {
+	return func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(
+			ctx context.Context, req *mcp.CallToolRequest,
+		) (*mcp.CallToolResult, any, error) {
+			call := toolCallFromContext(ctx)
+			logger := newSessionLogger(cfg, req.Session, call.name)
+
+			var fields []any
+			if cfg.LogOutputs {
+				fields = append(fields, "input", redactedInput(cfg, call.inputJSON))
+			}
+
+			result, out, err := next(ctx, req)
+			if err != nil || (result != nil && result.IsError) {
+				msg := errMessage(err, result)
+				errFields := append(append([]any{}, fields...), toolErrorLogFields(result)...)
+				logger.ErrorContext(ctx, msg, errFields...)
+				slog.ErrorContext(
+					ctx, msg, append([]any{"tool", call.name}, errFields...)...,
+				)
+				return result, out, err
+			}
+
+			if cfg.LogOutputs {
+				var outputLen int
+				if len(result.Content) > 0 {
+					if text, ok := result.Content[0].(*mcp.TextContent); ok {
+						outputLen = len(text.Text)
+					}
+				}
+				fields = append(fields, "output_length", outputLen)
+			}
+
+			logger.InfoContext(ctx, call.name, fields...)
+			slog.InfoContext(
+				ctx, call.name, append([]any{"tool", call.name}, fields...)...,
+			)
+			return result, out, nil
+		}
+	}
+}
+
+// RecoverMiddleware converts panics raised by op (or a downstream
+// middleware) into an error CallToolResult instead of crashing the server.
+func RecoverMiddleware() ToolMiddleware {
+	return func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(
+			ctx context.Context, req *mcp.CallToolRequest,
+		) (result *mcp.CallToolResult, out any, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					result = &mcp.CallToolResult{
+						IsError: true,
+						Content: []mcp.Content{
+							&mcp.TextContent{Text: fmt.Sprintf("panic: %v", r)},
+						},
+					}
+					out, err = nil, nil
+				}
+			}()
+			return next(ctx, req)
+		}
+	}
+}
+
+// TimeoutMiddleware bounds each call to d by deriving a context with a
+// deadline; op observes cancellation through ctx as usual.
+func TimeoutMiddleware(d time.Duration) ToolMiddleware {
+	return func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(
+			ctx context.Context, req *mcp.CallToolRequest,
+		) (*mcp.CallToolResult, any, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+			return next(ctx, req)
+		}
+	}
+}
+
+// rateLimitIdleTTL bounds how long a session's token bucket is kept after
+// its last call before RateLimitMiddleware evicts it. There is no session
+// close hook available here, so idle buckets are swept lazily instead.
+const rateLimitIdleTTL = 10 * time.Minute
+
+// rateLimitSweepInterval bounds how often RateLimitMiddleware scans for
+// idle buckets to evict, so the sweep itself stays cheap relative to the
+// rate of incoming calls.
+const rateLimitSweepInterval = time.Minute
+
+// RateLimitMiddleware enforces a token-bucket limit of rps requests per
+// second with the given burst size, tracked per MCP session. Calls that
+// exceed the limit fail fast with an error CallToolResult rather than
+// blocking. Buckets idle for longer than rateLimitIdleTTL are evicted on a
+// periodic sweep so long-lived servers with many short-lived sessions don't
+// grow the bucket map without bound.
+func RateLimitMiddleware(rps float64, burst int) ToolMiddleware {
+	var (
+		mu        sync.Mutex
+		buckets   = map[*mcp.ServerSession]*tokenBucket{}
+		lastSweep time.Time
+	)
+
+	return func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(
+			ctx context.Context, req *mcp.CallToolRequest,
+		) (*mcp.CallToolResult, any, error) {
+			mu.Lock()
+			now := time.Now()
+			if now.Sub(lastSweep) > rateLimitSweepInterval {
+				for session, bucket := range buckets {
+					if now.Sub(bucket.lastSeen()) > rateLimitIdleTTL {
+						delete(buckets, session)
+					}
+				}
+				lastSweep = now
+			}
+
+			bucket, ok := buckets[req.Session]
+			if !ok {
+				bucket = &tokenBucket{tokens: float64(burst), rps: rps, burst: burst}
+				buckets[req.Session] = bucket
+			}
+			mu.Unlock()
+
+			if !bucket.allow() {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{
+						&mcp.TextContent{Text: "rate limit exceeded"},
+					},
+				}, nil, nil
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+// tokenBucket is a simple thread-safe token bucket used by
+// RateLimitMiddleware.
+type tokenBucket struct {
+	mu       sync.Mutex
+	tokens   float64
+	rps      float64
+	burst    int
+	lastFill time.Time
+}
+
+// lastSeen reports when the bucket was last drawn from, used by
+// RateLimitMiddleware's idle sweep.
+func (b *tokenBucket) lastSeen() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastFill
+}
+
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if !b.lastFill.IsZero() {
+		b.tokens += now.Sub(b.lastFill).Seconds() * b.rps
+		if b.tokens > float64(b.burst) {
+			b.tokens = float64(b.burst)
+		}
+	}
+	b.lastFill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// ToolMetricsRecorder records per-tool-call outcomes. The internal/metrics
+// package provides the built-in Prometheus-backed implementation; callers
+// may supply their own.
+type ToolMetricsRecorder interface {
+	RecordToolCall(tool, status string, duration time.Duration)
+}
+
+// MetricsMiddleware records call duration and status through recorder.
+func MetricsMiddleware(recorder ToolMetricsRecorder) ToolMiddleware {
+	return func(next ToolHandlerFunc) ToolHandlerFunc {
+		return func(
+			ctx context.Context, req *mcp.CallToolRequest,
+		) (*mcp.CallToolResult, any, error) {
+			start := time.Now()
+			result, out, err := next(ctx, req)
+
+			status := "ok"
+			if err != nil || (result != nil && result.IsError) {
+				status = "error"
+			}
+			recorder.RecordToolCall(
+				toolCallFromContext(ctx).name, status, time.Since(start),
+			)
+			return result, out, err
+		}
+	}
+}
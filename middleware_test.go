@@ -0,0 +1,70 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTokenBucketAllow(t *testing.T) {
+	b := &tokenBucket{tokens: 2, rps: 100, burst: 2}
+
+	if !b.allow() {
+		t.Fatal("first call within burst should be allowed")
+	}
+	if !b.allow() {
+		t.Fatal("second call within burst should be allowed")
+	}
+	if b.allow() {
+		t.Fatal("call beyond burst should be denied")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("call after refill interval should be allowed")
+	}
+}
+
+func TestTokenBucketAllowCapsAtBurst(t *testing.T) {
+	b := &tokenBucket{tokens: 1, rps: 1000, burst: 1, lastFill: time.Now()}
+
+	time.Sleep(50 * time.Millisecond)
+	if !b.allow() {
+		t.Fatal("refill should not exceed burst, but a token should still be available")
+	}
+	if b.allow() {
+		t.Fatal("refill must cap at burst, not accumulate unboundedly")
+	}
+}
+
+func TestRateLimitMiddlewareDeniesBeyondBurst(t *testing.T) {
+	mw := RateLimitMiddleware(1, 1)
+	calls := 0
+	core := func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, any, error) {
+		calls++
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	handler := mw(core)
+	req := &mcp.CallToolRequest{}
+
+	result, _, err := handler(context.Background(), req)
+	if err != nil || result.IsError {
+		t.Fatalf("first call within burst should succeed, got result=%+v err=%v", result, err)
+	}
+
+	result, _, err = handler(context.Background(), req)
+	if err != nil {
+		t.Fatalf("rate-limited call should not return an error, got %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("call beyond the burst should return an error CallToolResult")
+	}
+	if calls != 1 {
+		t.Fatalf("rate-limited call must not reach the wrapped handler, calls=%d", calls)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime/debug"
+	"sync"
+
+	"github.com/eat-pray-ai/cobra-mcp/internal/metrics"
+)
+
+// ObservabilityOptions controls the debug and monitoring endpoints mounted
+// alongside (or in front of) the HTTP transport.
+type ObservabilityOptions struct {
+	// Enabled mounts /debug/vars, /metrics, /debug/pprof/*, /healthz, and
+	// /readyz.
+	Enabled bool
+
+	// AdminAddr, if set, serves the observability endpoints on a separate
+	// TCP listener instead of the main HTTP mode address, hardened with the
+	// same Config.TLSConfig/CertFile/KeyFile and Config.Authenticator as the
+	// main listener. Use this to keep /debug/pprof off a publicly reachable
+	// port.
+	AdminAddr string
+}
+
+var publishBuildInfoOnce sync.Once
+
+// mountObservability registers the observability endpoints on mux, backed
+// by m.
+func mountObservability(mux *http.ServeMux, cfg *Config, m *metrics.Metrics) {
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/metrics", m.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	publishBuildInfoOnce.Do(func() {
+		expvar.NewString("cobramcp.name").Set(cfg.Name)
+		expvar.NewString("cobramcp.version").Set(cfg.Version)
+		expvar.Publish("cobramcp.buildinfo", expvar.Func(func() any {
+			info, _ := debug.ReadBuildInfo()
+			return info
+		}))
+	})
+}
@@ -0,0 +1,28 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eat-pray-ai/cobra-mcp/internal/metrics"
+)
+
+func TestMountObservability(t *testing.T) {
+	mux := http.NewServeMux()
+	mountObservability(mux, &Config{Name: "test", Version: "v0.0.0"}, metrics.New("mount-observability-test"))
+
+	for _, path := range []string{
+		"/debug/vars", "/debug/pprof/", "/metrics", "/healthz", "/readyz",
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/eat-pray-ai/cobra-mcp/internal/metrics"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultReadHeaderTimeout   = 10 * time.Second
+	defaultShutdownGracePeriod = 10 * time.Second
+)
+
+// ErrForbidden can be returned (or wrapped) by Config.Authenticator to fail
+// a request with 403 Forbidden instead of the default 401 Unauthorized.
+var ErrForbidden = errors.New("forbidden")
+
+// BearerAuth returns an Authenticator that accepts requests whose
+// Authorization header is "Bearer <token>" for one of tokens.
+func BearerAuth(tokens ...string) func(*http.Request) error {
+	allowed := make(map[string]struct{}, len(tokens))
+	for _, t := range tokens {
+		allowed[t] = struct{}{}
+	}
+
+	return func(r *http.Request) error {
+		const prefix = "Bearer "
+		header := r.Header.Get("Authorization")
+		if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+			return fmt.Errorf("missing bearer token")
+		}
+		if _, ok := allowed[header[len(prefix):]]; !ok {
+			return fmt.Errorf("%w: invalid bearer token", ErrForbidden)
+		}
+		return nil
+	}
+}
+
+// BasicAuth returns an Authenticator that accepts requests whose HTTP Basic
+// credentials match an entry in userpass.
+func BasicAuth(userpass map[string]string) func(*http.Request) error {
+	return func(r *http.Request) error {
+		user, pass, ok := r.BasicAuth()
+		if !ok {
+			return fmt.Errorf("missing basic auth credentials")
+		}
+		want, ok := userpass[user]
+		if !ok || subtle.ConstantTimeCompare([]byte(want), []byte(pass)) != 1 {
+			return fmt.Errorf("%w: invalid credentials", ErrForbidden)
+		}
+		return nil
+	}
+}
+
+// authMiddleware wraps next with cfg.Authenticator, failing requests with
+// 401 Unauthorized (or 403 Forbidden when the error wraps ErrForbidden)
+// before they reach next.
+func authMiddleware(auth func(*http.Request) error, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := auth(r); err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrForbidden) {
+				status = http.StatusForbidden
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// activeSessionsPollInterval bounds how often pollActiveSessions refreshes
+// the active-session gauge.
+const activeSessionsPollInterval = 5 * time.Second
+
+// pollActiveSessions sets recorder's active-session gauge from
+// server.Sessions() on activeSessionsPollInterval, until ctx is canceled. A
+// streamable-HTTP session persists across many separate HTTP requests keyed
+// by the Mcp-Session-Id header, so request concurrency isn't a usable proxy
+// for it; server.Sessions() reflects the SDK's own session registry.
+func pollActiveSessions(ctx context.Context, recorder *metrics.Metrics, server *mcp.Server) {
+	ticker := time.NewTicker(activeSessionsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n := 0
+			for range server.Sessions() {
+				n++
+			}
+			recorder.SetActiveSessions(n)
+		}
+	}
+}
+
+// newHTTPServer builds an *http.Server for handler using cfg's timeout and
+// TLS settings, shared by the main and admin listeners so both get the same
+// hardening.
+func newHTTPServer(cfg *Config, handler http.Handler) *http.Server {
+	readHeaderTimeout := cfg.ReadHeaderTimeout
+	if readHeaderTimeout == 0 {
+		readHeaderTimeout = defaultReadHeaderTimeout
+	}
+
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		TLSConfig:         cfg.TLSConfig,
+	}
+}
+
+// serveHTTP starts srv serving ln in a goroutine, choosing TLS the same way
+// cfg requests it for the main listener, and reports the terminal error (or
+// nil on a clean Shutdown) on the returned channel.
+func serveHTTP(cfg *Config, srv *http.Server, ln net.Listener) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		var err error
+		switch {
+		case cfg.TLSConfig != nil:
+			err = srv.ServeTLS(ln, "", "")
+		case cfg.CertFile != "" || cfg.KeyFile != "":
+			err = srv.ServeTLS(ln, cfg.CertFile, cfg.KeyFile)
+		default:
+			err = srv.Serve(ln)
+		}
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		errCh <- err
+	}()
+	return errCh
+}
+
+// authWrap wraps handler with cfg.Authenticator, if set.
+func authWrap(cfg *Config, handler http.Handler) http.Handler {
+	if cfg.Authenticator == nil {
+		return handler
+	}
+	return authMiddleware(cfg.Authenticator, handler)
+}
+
+// runHTTP serves the MCP streamable-HTTP handler (and, if enabled, the
+// observability endpoints) until ctx is canceled, at which point it drains
+// in-flight requests for up to Config.ShutdownGracePeriod before returning.
+func runHTTP(ctx context.Context, cfg *Config, server *mcp.Server, port int) error {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", mcp.NewStreamableHTTPHandler(
+		func(*http.Request) *mcp.Server {
+			return server
+		}, nil,
+	))
+
+	if cfg.metrics != nil {
+		go pollActiveSessions(ctx, cfg.metrics, server)
+	}
+
+	proto := cfg.ListenProto
+	if proto == "" {
+		proto = "tcp"
+	}
+
+	listenAddr := cfg.ListenAddr
+	if listenAddr == "" {
+		if proto == "unix" {
+			return fmt.Errorf("ListenAddr is required when ListenProto is %q", proto)
+		}
+		listenAddr = fmt.Sprintf(":%d", port)
+	}
+	if proto == "unix" {
+		_ = os.Remove(listenAddr)
+	}
+
+	ln, err := net.Listen(proto, listenAddr)
+	if err != nil {
+		return fmt.Errorf("listening on %s %s: %w", proto, listenAddr, err)
+	}
+
+	slog.InfoContext(
+		ctx, "http server configuration",
+		"proto", proto, "addr", listenAddr,
+		"url", fmt.Sprintf("http://localhost:%d/mcp", port),
+	)
+
+	srv := newHTTPServer(cfg, authWrap(cfg, mux))
+	serveErrCh := serveHTTP(cfg, srv, ln)
+
+	// adminSrv stays nil unless Observability.AdminAddr carves out its own
+	// listener; it gets the same TLS/auth/timeout treatment as srv so
+	// /debug/pprof and /metrics aren't an unauthenticated side door.
+	var adminSrv *http.Server
+	var adminErrCh <-chan error
+	if cfg.Observability != nil && cfg.Observability.Enabled {
+		if cfg.Observability.AdminAddr == "" {
+			mountObservability(mux, cfg, cfg.metrics)
+		} else {
+			adminLn, err := net.Listen("tcp", cfg.Observability.AdminAddr)
+			if err != nil {
+				return fmt.Errorf(
+					"listening on admin addr %s: %w",
+					cfg.Observability.AdminAddr, err,
+				)
+			}
+
+			adminMux := http.NewServeMux()
+			mountObservability(adminMux, cfg, cfg.metrics)
+
+			slog.InfoContext(
+				ctx, "observability server configuration",
+				"addr", cfg.Observability.AdminAddr,
+			)
+			adminSrv = newHTTPServer(cfg, authWrap(cfg, adminMux))
+			adminErrCh = serveHTTP(cfg, adminSrv, adminLn)
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		gracePeriod := cfg.ShutdownGracePeriod
+		if gracePeriod == 0 {
+			gracePeriod = defaultShutdownGracePeriod
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+		defer cancel()
+
+		slog.InfoContext(ctx, "shutting down http server", "grace_period", gracePeriod)
+		err := srv.Shutdown(shutdownCtx)
+		if adminSrv != nil {
+			if adminErr := adminSrv.Shutdown(shutdownCtx); err == nil {
+				err = adminErr
+			}
+		}
+		return err
+	case err := <-serveErrCh:
+		return err
+	case err := <-adminErrCh:
+		return err
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright 2026 eat-pray-ai & OpenWaygate
+// SPDX-License-Identifier: Apache-2.0
+
+package cobramcp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eat-pray-ai/cobra-mcp/internal/metrics"
+)
+
+func TestBearerAuth(t *testing.T) {
+	auth := BearerAuth("good-token")
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer good-token")
+	if err := auth(req); err != nil {
+		t.Fatalf("valid token should be accepted, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.Header.Set("Authorization", "Bearer wrong-token")
+	err := auth(req)
+	if err == nil || !errors.Is(err, ErrForbidden) {
+		t.Fatalf("invalid token should be forbidden, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if err := auth(req); err == nil {
+		t.Fatal("missing Authorization header should be rejected")
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	auth := BasicAuth(map[string]string{"alice": "s3cret"})
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.SetBasicAuth("alice", "s3cret")
+	if err := auth(req); err != nil {
+		t.Fatalf("valid credentials should be accepted, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	req.SetBasicAuth("alice", "wrong")
+	err := auth(req)
+	if err == nil || !errors.Is(err, ErrForbidden) {
+		t.Fatalf("wrong password should be forbidden, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if err := auth(req); err == nil {
+		t.Fatal("missing credentials should be rejected")
+	}
+}
+
+func TestAuthMiddlewareStatusCodes(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	forbidden := authMiddleware(func(*http.Request) error {
+		return errors.New("nope: " + ErrForbidden.Error())
+	}, okHandler)
+	rec := httptest.NewRecorder()
+	forbidden.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("non-ErrForbidden auth error should 401, got %d", rec.Code)
+	}
+
+	forbidden = authMiddleware(func(*http.Request) error {
+		return ErrForbidden
+	}, okHandler)
+	rec = httptest.NewRecorder()
+	forbidden.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("ErrForbidden auth error should 403, got %d", rec.Code)
+	}
+
+	allowed := authMiddleware(func(*http.Request) error { return nil }, okHandler)
+	rec = httptest.NewRecorder()
+	allowed.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("nil auth error should reach next, got %d", rec.Code)
+	}
+}
+
+// freeAddr returns a loopback address with an OS-assigned free port, by
+// opening and immediately closing a listener on it.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("finding a free port: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// waitForListener blocks until addr accepts TCP connections or the deadline
+// passes.
+func waitForListener(t *testing.T, addr string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		conn, err := net.DialTimeout("tcp", addr, 50*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("nothing listening on %s after deadline", addr)
+}
+
+func TestRunHTTPMountsObservabilityEndpoints(t *testing.T) {
+	cfg := &Config{
+		Name:          "test",
+		Version:       "v0.0.0",
+		ListenAddr:    freeAddr(t),
+		Observability: &ObservabilityOptions{Enabled: true},
+	}
+	cfg.metrics = metrics.New("run-http-observability-test")
+	server := newServer(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- runHTTP(ctx, cfg, server, 0) }()
+	waitForListener(t, cfg.ListenAddr)
+
+	base := "http://" + cfg.ListenAddr
+	for _, path := range []string{"/healthz", "/readyz", "/metrics"} {
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET %s: status = %d, want %d", path, resp.StatusCode, http.StatusOK)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("runHTTP returned %v after a clean shutdown", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("runHTTP did not return after its context was canceled")
+	}
+}
+
+func TestRunHTTPAdminListenerEnforcesAuthenticator(t *testing.T) {
+	cfg := &Config{
+		Name:       "test",
+		Version:    "v0.0.0",
+		ListenAddr: freeAddr(t),
+		Observability: &ObservabilityOptions{
+			Enabled:   true,
+			AdminAddr: freeAddr(t),
+		},
+		Authenticator: BearerAuth("admin-secret"),
+	}
+	cfg.metrics = metrics.New("run-http-admin-test")
+	server := newServer(cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- runHTTP(ctx, cfg, server, 0) }()
+	waitForListener(t, cfg.Observability.AdminAddr)
+
+	adminURL := "http://" + cfg.Observability.AdminAddr + "/metrics"
+
+	resp, err := http.Get(adminURL)
+	if err != nil {
+		t.Fatalf("GET %s: %v", adminURL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf(
+			"admin listener without credentials: status = %d, want %d",
+			resp.StatusCode, http.StatusUnauthorized,
+		)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, adminURL, nil)
+	req.Header.Set("Authorization", "Bearer admin-secret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s with credentials: %v", adminURL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf(
+			"admin listener with valid credentials: status = %d, want %d",
+			resp.StatusCode, http.StatusOK,
+		)
+	}
+
+	// The main listener requires the same Authenticator as the admin one.
+	mainResp, err := http.Get("http://" + cfg.ListenAddr + "/mcp")
+	if err != nil {
+		t.Fatalf("GET main listener: %v", err)
+	}
+	mainResp.Body.Close()
+	if mainResp.StatusCode != http.StatusUnauthorized {
+		t.Errorf(
+			"main listener without credentials: status = %d, want %d",
+			mainResp.StatusCode, http.StatusUnauthorized,
+		)
+	}
+
+	cancel()
+	select {
+	case err = <-errCh:
+		if err != nil {
+			t.Fatalf("runHTTP returned %v after a clean shutdown", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("runHTTP did not return after its context was canceled")
+	}
+}
+
+func TestPollActiveSessions(t *testing.T) {
+	server := newServer(&Config{Name: "test", Version: "v0.0.0"})
+	m := metrics.New("poll-active-sessions-test")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		pollActiveSessions(ctx, m, server)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("pollActiveSessions did not return after its context was canceled")
+	}
+}